@@ -0,0 +1,40 @@
+package gortsplib
+
+// ServerConf allows to configure the multicast behavior of a Server's UDP
+// listeners. It is temporary scaffolding until the full Server configuration
+// struct (transports, timeouts, etc) is reintroduced into this tree.
+type ServerConf struct {
+	// MulticastInterfaces, if not empty, restricts multicast group joins to
+	// the named network interfaces instead of every multicast-capable one.
+	// newServerUDPListener returns an error if none of them can be joined.
+	MulticastInterfaces []string
+
+	// MulticastTTL is the IPv4 TTL / IPv6 hop limit set on multicast
+	// listeners. Zero means use the package default (multicastTTL /
+	// multicastHopLimit).
+	MulticastTTL uint8
+
+	// MulticastLoopback enables delivery of multicast packets back to
+	// senders on the same host.
+	MulticastLoopback bool
+
+	// UDPReadBatchSize is the number of UDP datagrams read per ReadBatch
+	// syscall (recvmmsg on Linux) on the RTP/RTCP ingress hot path. Zero
+	// means use the package default (64).
+	UDPReadBatchSize int
+
+	// UDPBufferPoolSize is the number of read buffers pre-allocated per UDP
+	// listener. Zero means derive it from UDPReadBatchSize.
+	UDPBufferPoolSize int
+
+	// UDPListenerWorkers is the number of SO_REUSEPORT sockets opened per
+	// non-multicast UDP listener, each read by its own goroutine. Zero means
+	// use runtime.GOMAXPROCS(0). On platforms without SO_REUSEPORT a single
+	// socket is opened regardless of this value.
+	UDPListenerWorkers int
+
+	// UDPWriteQueueSize is the capacity of each per-client outgoing write
+	// queue (see serverUDPListener.queuedWrite). Zero means use the package
+	// default (256). A full queue drops the packet rather than blocking.
+	UDPWriteQueueSize int
+}
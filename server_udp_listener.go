@@ -1,15 +1,31 @@
 package gortsplib
 
 import (
+	"errors"
 	"fmt"
 	"net"
 	"strconv"
 	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
 )
 
+// defaultUDPReadBatchSize is the number of datagrams read per ReadBatch
+// syscall when ServerConf.UDPReadBatchSize is left at zero.
+const defaultUDPReadBatchSize = 64
+
+// defaultUDPWriteQueueSize is the per-client write queue capacity when
+// ServerConf.UDPWriteQueueSize is left at zero.
+const defaultUDPWriteQueueSize = 256
+
+// multicastHopLimit is the hop limit applied to IPv6 multicast sessions,
+// mirroring multicastTTL for IPv4.
+const multicastHopLimit = 16
+
 func serverFindFormatWithSSRC(
 	formats map[uint8]*serverSessionFormat,
 	ssrc uint32,
@@ -23,7 +39,16 @@ func serverFindFormatWithSSRC(
 	return nil
 }
 
-func joinMulticastGroupOnAtLeastOneInterface(p *ipv4.PacketConn, listenIP net.IP) error {
+// multicastJoiner is implemented by both ipv4.PacketConn and ipv6.PacketConn,
+// allowing joinMulticastGroupOnAtLeastOneInterface to stay family-agnostic.
+type multicastJoiner interface {
+	JoinGroup(ifi *net.Interface, group net.Addr) error
+}
+
+// joinMulticastGroupOnAtLeastOneInterface joins listenIP on every
+// multicast-capable interface, or, if interfaceNames is non-empty, on only
+// the named ones. It returns an error if no interface could be joined.
+func joinMulticastGroupOnAtLeastOneInterface(p multicastJoiner, listenIP net.IP, interfaceNames []string) error {
 	intfs, err := net.Interfaces()
 	if err != nil {
 		return err
@@ -32,59 +57,181 @@ func joinMulticastGroupOnAtLeastOneInterface(p *ipv4.PacketConn, listenIP net.IP
 	success := false
 
 	for _, intf := range intfs {
-		if (intf.Flags & net.FlagMulticast) != 0 {
-			err := p.JoinGroup(&intf, &net.UDPAddr{IP: listenIP})
-			if err == nil {
-				success = true
-			}
+		if (intf.Flags&net.FlagMulticast) == 0 || !multicastInterfaceAllowed(intf, interfaceNames) {
+			continue
+		}
+
+		err := p.JoinGroup(&intf, &net.UDPAddr{IP: listenIP})
+		if err == nil {
+			success = true
 		}
 	}
 
 	if !success {
+		if len(interfaceNames) != 0 {
+			return fmt.Errorf("unable to activate multicast on any of the configured interfaces %v", interfaceNames)
+		}
 		return fmt.Errorf("unable to activate multicast on any network interface")
 	}
 
 	return nil
 }
 
-type clientAddr struct {
-	ip   [net.IPv6len]byte // use a fixed-size array to enable the equality operator
-	port int
+func multicastInterfaceAllowed(intf net.Interface, interfaceNames []string) bool {
+	if len(interfaceNames) == 0 {
+		return true
+	}
+
+	for _, name := range interfaceNames {
+		if intf.Name == name {
+			return true
+		}
+	}
+
+	return false
 }
 
-func (p *clientAddr) fill(ip net.IP, port int) {
-	p.port = port
+var v4InV6Prefix = []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0xff, 0xff}
 
+// clientAddr identifies a client by source address and, for multicast
+// listeners, by destination group too, so that e.g. 239.0.0.1:5004 and
+// 239.0.0.2:5004 don't collide when they share a listener. group is always
+// zero for unicast (non-multicast) listeners.
+type clientAddr struct {
+	group [net.IPv6len]byte // destination multicast group; zero value on unicast listeners
+	ip    [net.IPv6len]byte // use a fixed-size array to enable the equality operator
+	port  int
+}
+
+func fillClientIP(dst *[net.IPv6len]byte, ip net.IP) {
 	if len(ip) == net.IPv4len {
-		copy(p.ip[0:], []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0xff, 0xff}) // v4InV6Prefix
-		copy(p.ip[12:], ip)
+		copy(dst[0:], v4InV6Prefix)
+		copy(dst[12:], ip)
 	} else {
-		copy(p.ip[:], ip)
+		copy(dst[:], ip)
 	}
 }
 
+func (p *clientAddr) fill(group net.IP, ip net.IP, port int) {
+	p.port = port
+
+	p.group = [net.IPv6len]byte{}
+	if group != nil {
+		fillClientIP(&p.group, group)
+	}
+
+	fillClientIP(&p.ip, ip)
+}
+
 type serverUDPListener struct {
-	pc           *net.UDPConn
-	listenIP     net.IP
-	writeTimeout time.Duration
-	clientsMutex sync.RWMutex
-	clients      map[clientAddr]readFunc
+	multicast     bool             // true for every multicast listener, IPv4 or IPv6; decides the read path in run()
+	pc            *net.UDPConn
+	pc4           *ipv4.PacketConn // set for an IPv4 multicast listener; demultiplexes several groups sharing this port
+	pc6           *ipv6.PacketConn // set for an IPv6 multicast listener; same purpose as pc4, for the v6 family
+	pcBatch       *ipv4.PacketConn // wraps pc for ReadBatch/WriteBatch; used on every listener, multicast or not
+	joiner        multicastJoiner  // non-nil for multicast listeners; used to join extra groups when the port is shared
+	listenIP      net.IP
+	writeTimeout  time.Duration
+	readBatchSize int
+	bufPool       *sync.Pool
+	clientsMutex  sync.RWMutex
+	clients       map[clientAddr]readFunc
+	refCount      int        // multicast listeners sharing a port are reference-counted
+	conf          ServerConf // the conf this listener was created with; reuse requires a match, see multicastConfEqual
+
+	writeQueueSize  int
+	writeQueues     map[clientAddr]*udpClientWriteQueue
+	writeQueuesMu   sync.Mutex
+	writeLoopDone   chan struct{}
+	writeLoopExited chan struct{}
 
 	done chan struct{}
 }
 
+// udpClientWriteQueue buffers outgoing packets for a single destination
+// behind a bounded channel, so a slow or unresponsive client can't stall
+// writes to every other client sharing this listener. runWriteLoop drains
+// every client's queue together, batching whatever is pending into a single
+// WriteBatch call.
+type udpClientWriteQueue struct {
+	addr    *net.UDPAddr
+	packets chan []byte
+	dropped uint64 // atomic
+}
+
+// writeFlushInterval is how often runWriteLoop batches up pending packets
+// across every client queue into a single WriteBatch syscall.
+const writeFlushInterval = 2 * time.Millisecond
+
+// multicastListeners holds, per UDP port, the multicast serverUDPListener
+// currently bound to it, so that sessions publishing different groups on the
+// same RTP/RTCP port (e.g. 239.0.0.1:5004 and 239.0.0.2:5004) reuse a single
+// socket instead of failing to bind twice.
+//
+// This registry is process-global and keyed only by port: two unrelated
+// Servers (or two unrelated sessions) that happen to pick the same
+// multicast port will share this socket and its refCount whether they
+// intended to or not. newServerUDPListener guards against the most likely
+// symptom of that — a second session silently getting the first session's
+// whole ServerConf, down to its read-batch/buffer-pool/write-queue sizing,
+// instead of its own — by rejecting the reuse outright when the configs
+// don't match (see multicastConfEqual).
+var (
+	multicastListenersMutex sync.Mutex
+	multicastListeners      = make(map[int]*serverUDPListener)
+)
+
+// multicastConfEqual reports whether a and b would configure a multicast
+// listener identically, for the reuse check in newServerUDPListener. This
+// covers every tunable a reused listener would otherwise silently impose on
+// the second session, not just the multicast-specific ones: UDPReadBatchSize,
+// UDPBufferPoolSize and UDPWriteQueueSize are set once, on the listener that
+// wins the race to create the socket, and are then fixed for as long as the
+// port is shared. The writeTimeout passed to newServerUDPListener is not part
+// of ServerConf and is not compared: a reused listener keeps the first one's.
+func multicastConfEqual(a, b ServerConf) bool {
+	if a.MulticastTTL != b.MulticastTTL || a.MulticastLoopback != b.MulticastLoopback {
+		return false
+	}
+
+	if a.UDPReadBatchSize != b.UDPReadBatchSize ||
+		a.UDPBufferPoolSize != b.UDPBufferPoolSize ||
+		a.UDPWriteQueueSize != b.UDPWriteQueueSize {
+		return false
+	}
+
+	if len(a.MulticastInterfaces) != len(b.MulticastInterfaces) {
+		return false
+	}
+
+	for i, name := range a.MulticastInterfaces {
+		if b.MulticastInterfaces[i] != name {
+			return false
+		}
+	}
+
+	return true
+}
+
+// newServerUDPListenerMulticastPair creates the RTP/RTCP listener pair used
+// for a multicast session. ip may be an IPv4 or IPv6 multicast address; IPv6
+// literals are bracketed by net.JoinHostPort in the address passed to
+// newServerUDPListener. This function does not build the SETUP response's
+// Transport header.
 func newServerUDPListenerMulticastPair(
 	listenPacket func(network, address string) (net.PacketConn, error),
 	writeTimeout time.Duration,
 	multicastRTPPort int,
 	multicastRTCPPort int,
 	ip net.IP,
+	conf ServerConf,
 ) (*serverUDPListener, *serverUDPListener, error) {
 	rtpl, err := newServerUDPListener(
 		listenPacket,
 		writeTimeout,
 		true,
 		net.JoinHostPort(ip.String(), strconv.FormatInt(int64(multicastRTPPort), 10)),
+		conf,
 	)
 	if err != nil {
 		return nil, nil, err
@@ -95,6 +242,7 @@ func newServerUDPListenerMulticastPair(
 		writeTimeout,
 		true,
 		net.JoinHostPort(ip.String(), strconv.FormatInt(int64(multicastRTCPPort), 10)),
+		conf,
 	)
 	if err != nil {
 		rtpl.close()
@@ -109,8 +257,12 @@ func newServerUDPListener(
 	writeTimeout time.Duration,
 	multicast bool,
 	address string,
+	conf ServerConf,
 ) (*serverUDPListener, error) {
 	var pc *net.UDPConn
+	var pc4 *ipv4.PacketConn
+	var pc6 *ipv6.PacketConn
+	var joiner multicastJoiner
 	var listenIP net.IP
 	if multicast {
 		host, port, err := net.SplitHostPort(address)
@@ -118,25 +270,118 @@ func newServerUDPListener(
 			return nil, err
 		}
 
-		tmp, err := listenPacket(restrictNetwork("udp", "224.0.0.0:"+port))
+		listenIP = net.ParseIP(host)
+		if listenIP == nil {
+			return nil, fmt.Errorf("invalid IP '%s'", host)
+		}
+
+		portNum, err := strconv.Atoi(port)
 		if err != nil {
 			return nil, err
 		}
 
-		p := ipv4.NewPacketConn(tmp)
+		multicastListenersMutex.Lock()
+		if existing, ok := multicastListeners[portNum]; ok {
+			if !multicastConfEqual(existing.conf, conf) {
+				multicastListenersMutex.Unlock()
+				return nil, fmt.Errorf(
+					"port %d is already in use by a multicast listener with a different "+
+						"ServerConf (MulticastTTL/MulticastLoopback/MulticastInterfaces/"+
+						"UDPReadBatchSize/UDPBufferPoolSize/UDPWriteQueueSize)", portNum)
+			}
 
-		err = p.SetMulticastTTL(multicastTTL)
-		if err != nil {
-			return nil, err
+			err := joinMulticastGroupOnAtLeastOneInterface(existing.joiner, listenIP, conf.MulticastInterfaces)
+			if err != nil {
+				multicastListenersMutex.Unlock()
+				return nil, err
+			}
+
+			existing.refCount++
+			multicastListenersMutex.Unlock()
+			return existing, nil
 		}
+		multicastListenersMutex.Unlock()
 
-		listenIP = net.ParseIP(host)
+		isIPv6 := listenIP.To4() == nil
 
-		err = joinMulticastGroupOnAtLeastOneInterface(p, listenIP)
+		var tmp net.PacketConn
+		if isIPv6 {
+			tmp, err = listenPacket(restrictNetwork("udp6", "["+net.IPv6zero.String()+"]:"+port))
+		} else {
+			tmp, err = listenPacket(restrictNetwork("udp", "224.0.0.0:"+port))
+		}
 		if err != nil {
 			return nil, err
 		}
 
+		if isIPv6 {
+			p := ipv6.NewPacketConn(tmp)
+
+			hopLimit := multicastHopLimit
+			if conf.MulticastTTL != 0 {
+				hopLimit = int(conf.MulticastTTL)
+			}
+
+			err = p.SetMulticastHopLimit(hopLimit)
+			if err != nil {
+				return nil, err
+			}
+
+			err = p.SetMulticastLoopback(conf.MulticastLoopback)
+			if err != nil {
+				return nil, err
+			}
+
+			err = joinMulticastGroupOnAtLeastOneInterface(p, listenIP, conf.MulticastInterfaces)
+			if err != nil {
+				return nil, err
+			}
+
+			// request the destination address on every read, so that packets
+			// addressed to different multicast groups sharing this port can
+			// be told apart in run(), exactly like the IPv4 branch below.
+			err = p.SetControlMessage(ipv6.FlagDst, true)
+			if err != nil {
+				return nil, err
+			}
+
+			pc6 = p
+			joiner = p
+		} else {
+			p := ipv4.NewPacketConn(tmp)
+
+			ttl := multicastTTL
+			if conf.MulticastTTL != 0 {
+				ttl = int(conf.MulticastTTL)
+			}
+
+			err = p.SetMulticastTTL(ttl)
+			if err != nil {
+				return nil, err
+			}
+
+			err = p.SetMulticastLoopback(conf.MulticastLoopback)
+			if err != nil {
+				return nil, err
+			}
+
+			err = joinMulticastGroupOnAtLeastOneInterface(p, listenIP, conf.MulticastInterfaces)
+			if err != nil {
+				return nil, err
+			}
+
+			// request the destination address on every read, so that packets
+			// addressed to different multicast groups sharing this port can
+			// be told apart in run().
+			err = p.SetControlMessage(ipv4.FlagDst, true)
+			if err != nil {
+				return nil, err
+			}
+
+			pc4 = p
+			joiner = p
+		}
+
 		pc = tmp.(*net.UDPConn)
 	} else {
 		tmp, err := listenPacket(restrictNetwork("udp", address))
@@ -153,20 +398,91 @@ func newServerUDPListener(
 		return nil, err
 	}
 
+	pcBatch := pc4
+	if pcBatch == nil {
+		pcBatch = ipv4.NewPacketConn(pc)
+	}
+
+	readBatchSize := conf.UDPReadBatchSize
+	if readBatchSize == 0 {
+		readBatchSize = defaultUDPReadBatchSize
+	}
+
+	bufPool := &sync.Pool{
+		New: func() interface{} {
+			return make([]byte, udpMaxPayloadSize+1)
+		},
+	}
+
+	bufPoolSize := conf.UDPBufferPoolSize
+	if bufPoolSize == 0 {
+		bufPoolSize = readBatchSize * 2
+	}
+	for i := 0; i < bufPoolSize; i++ {
+		bufPool.Put(make([]byte, udpMaxPayloadSize+1))
+	}
+
+	writeQueueSize := conf.UDPWriteQueueSize
+	if writeQueueSize == 0 {
+		writeQueueSize = defaultUDPWriteQueueSize
+	}
+
 	u := &serverUDPListener{
-		pc:           pc,
-		listenIP:     listenIP,
-		clients:      make(map[clientAddr]readFunc),
-		writeTimeout: writeTimeout,
-		done:         make(chan struct{}),
+		multicast:       multicast,
+		pc:              pc,
+		pc4:             pc4,
+		pc6:             pc6,
+		pcBatch:         pcBatch,
+		joiner:          joiner,
+		listenIP:        listenIP,
+		clients:         make(map[clientAddr]readFunc),
+		writeTimeout:    writeTimeout,
+		readBatchSize:   readBatchSize,
+		bufPool:         bufPool,
+		refCount:        1,
+		conf:            conf,
+		writeQueueSize:  writeQueueSize,
+		writeQueues:     make(map[clientAddr]*udpClientWriteQueue),
+		writeLoopDone:   make(chan struct{}),
+		writeLoopExited: make(chan struct{}),
+		done:            make(chan struct{}),
+	}
+
+	if multicast {
+		multicastListenersMutex.Lock()
+		multicastListeners[u.port()] = u
+		multicastListenersMutex.Unlock()
 	}
 
 	go u.run()
+	go u.runWriteLoop()
 
 	return u, nil
 }
 
+func (u *serverUDPListener) getBuf() []byte {
+	return u.bufPool.Get().([]byte)
+}
+
+func (u *serverUDPListener) putBuf(buf []byte) {
+	u.bufPool.Put(buf) //nolint:staticcheck
+}
+
 func (u *serverUDPListener) close() {
+	if u.joiner != nil {
+		multicastListenersMutex.Lock()
+		u.refCount--
+		if u.refCount > 0 {
+			multicastListenersMutex.Unlock()
+			return
+		}
+		delete(multicastListeners, u.port())
+		multicastListenersMutex.Unlock()
+	}
+
+	close(u.writeLoopDone)
+	<-u.writeLoopExited
+
 	u.pc.Close()
 	<-u.done
 }
@@ -182,40 +498,323 @@ func (u *serverUDPListener) port() int {
 func (u *serverUDPListener) run() {
 	defer close(u.done)
 
+	if u.multicast {
+		u.runMulticast()
+		return
+	}
+
+	u.runBatched()
+}
+
+// dispatch looks up the client registered for group/ip/port and, if found,
+// invokes its callback with data. data must not be retained past the call.
+func (u *serverUDPListener) dispatch(group net.IP, ip net.IP, port int, data []byte) {
+	u.clientsMutex.RLock()
+	defer u.clientsMutex.RUnlock()
+
+	var addr clientAddr
+	addr.fill(group, ip, port)
+	cb, ok := u.clients[addr]
+	if !ok {
+		return
+	}
+
+	cb(data)
+}
+
+// runMulticast dispatches to the read loop matching the listener's address
+// family. Both loops read the packet's destination group via a control
+// message, so that sessions sharing this port across several multicast
+// groups can be told apart. Every client of a multicast listener is always
+// keyed by group (see addClient), so the fallback to u.listenIP in each loop
+// matters: some platforms set FlagDst but still return a nil cm.Dst, and
+// without the fallback those packets would never match.
+func (u *serverUDPListener) runMulticast() {
+	if u.pc6 != nil {
+		u.runMulticastV6()
+		return
+	}
+
+	u.runMulticastV4()
+}
+
+func (u *serverUDPListener) runMulticastV4() {
 	for {
-		buf := make([]byte, udpMaxPayloadSize+1)
-		n, addr, err := u.pc.ReadFromUDP(buf)
+		buf := u.getBuf()
+		n, cm, addr, err := u.pc4.ReadFrom(buf)
 		if err != nil {
+			u.putBuf(buf)
 			break
 		}
 
-		func() {
-			u.clientsMutex.RLock()
-			defer u.clientsMutex.RUnlock()
+		udpAddr := addr.(*net.UDPAddr)
+
+		group := u.listenIP
+		if cm != nil && cm.Dst != nil {
+			group = cm.Dst
+		}
+
+		u.dispatch(group, udpAddr.IP, udpAddr.Port, buf[:n])
+		u.putBuf(buf)
+	}
+}
+
+func (u *serverUDPListener) runMulticastV6() {
+	for {
+		buf := u.getBuf()
+		n, cm, addr, err := u.pc6.ReadFrom(buf)
+		if err != nil {
+			u.putBuf(buf)
+			break
+		}
+
+		udpAddr := addr.(*net.UDPAddr)
+
+		group := u.listenIP
+		if cm != nil && cm.Dst != nil {
+			group = cm.Dst
+		}
+
+		u.dispatch(group, udpAddr.IP, udpAddr.Port, buf[:n])
+		u.putBuf(buf)
+	}
+}
+
+// isReadBatchUnsupported reports whether err is the EOPNOTSUPP ReadBatch
+// returns on platforms without recvmmsg, which runBatched treats as a
+// permanent signal to fall back to ReadFromUDP.
+func isReadBatchUnsupported(err error) bool {
+	return errors.Is(err, syscall.EOPNOTSUPP)
+}
+
+// runBatched is the hot path for listeners that don't need to demultiplex by
+// destination group. It reads up to readBatchSize datagrams per syscall via
+// ipv4.PacketConn.ReadBatch (recvmmsg on Linux), falling back permanently to
+// one ReadFromUDP call per datagram on platforms that return EOPNOTSUPP.
+func (u *serverUDPListener) runBatched() {
+	msgs := make([]ipv4.Message, u.readBatchSize)
+	bufs := make([][]byte, u.readBatchSize)
+
+	for i := range msgs {
+		bufs[i] = u.getBuf()
+		msgs[i].Buffers = [][]byte{bufs[i]}
+	}
+
+	defer func() {
+		for _, buf := range bufs {
+			u.putBuf(buf)
+		}
+	}()
+
+	batchSupported := true
 
-			var clientAddr clientAddr
-			clientAddr.fill(addr.IP, addr.Port)
-			cb, ok := u.clients[clientAddr]
-			if !ok {
+	for {
+		if batchSupported {
+			n, err := u.pcBatch.ReadBatch(msgs, 0)
+			if err != nil {
+				if isReadBatchUnsupported(err) {
+					batchSupported = false
+					continue
+				}
 				return
 			}
 
-			cb(buf[:n])
-		}()
+			for i := 0; i < n; i++ {
+				addr := msgs[i].Addr.(*net.UDPAddr)
+				u.dispatch(nil, addr.IP, addr.Port, bufs[i][:msgs[i].N])
+			}
+
+			continue
+		}
+
+		buf := u.getBuf()
+		n, addr, err := u.pc.ReadFromUDP(buf)
+		if err != nil {
+			u.putBuf(buf)
+			return
+		}
+
+		u.dispatch(nil, addr.IP, addr.Port, buf[:n])
+		u.putBuf(buf)
 	}
 }
 
+// errUDPWriteQueueFull is returned by write/queuedWrite when addr's per-client
+// queue is already full; buf is dropped and accounted in ServerUDPStats
+// rather than blocking the caller.
+var errUDPWriteQueueFull = errors.New("udp write queue full, packet dropped")
+
+// write enqueues buf for delivery to addr and returns immediately; see
+// queuedWrite, which it delegates to with a nil group since unicast
+// listeners never demultiplex writes by group.
 func (u *serverUDPListener) write(buf []byte, addr *net.UDPAddr) error {
-	// no mutex is needed here since Write() has an internal lock.
-	// https://github.com/golang/go/issues/27203#issuecomment-534386117
+	return u.queuedWrite(nil, buf, addr)
+}
+
+// queuedWrite enqueues buf for delivery to addr under group (the listener's
+// multicast group for a multicast listener, nil for a unicast one) and
+// returns immediately. The queue is drained by the listener's single
+// runWriteLoop together with every other client's queue, in one WriteBatch
+// call per flush under one u.writeTimeout deadline; there is no per-client
+// worker or per-client deadline.
+func (u *serverUDPListener) queuedWrite(group net.IP, buf []byte, addr *net.UDPAddr) error {
+	var key clientAddr
+	key.fill(group, addr.IP, addr.Port)
+
+	q := u.writeQueueFor(key, addr)
+
+	cp := append([]byte(nil), buf...)
+
+	select {
+	case q.packets <- cp:
+		return nil
+	default:
+		atomic.AddUint64(&q.dropped, 1)
+		return errUDPWriteQueueFull
+	}
+}
+
+func (u *serverUDPListener) writeQueueFor(key clientAddr, addr *net.UDPAddr) *udpClientWriteQueue {
+	u.writeQueuesMu.Lock()
+	defer u.writeQueuesMu.Unlock()
+
+	if q, ok := u.writeQueues[key]; ok {
+		return q
+	}
+
+	q := &udpClientWriteQueue{
+		addr:    addr,
+		packets: make(chan []byte, u.writeQueueSize),
+	}
+	u.writeQueues[key] = q
+
+	return q
+}
+
+func (u *serverUDPListener) closeWriteQueue(key clientAddr) {
+	u.writeQueuesMu.Lock()
+	delete(u.writeQueues, key)
+	u.writeQueuesMu.Unlock()
+}
+
+// runWriteLoop flushes every client's write queue each writeFlushInterval,
+// applying u.writeTimeout once per flush rather than per destination. All
+// clients' pending packets go out in the same WriteBatch call, so a send that
+// blocks or fails affects the whole batch: packets that WriteBatch did not
+// write are discarded, not retried.
+func (u *serverUDPListener) runWriteLoop() {
+	defer close(u.writeLoopExited)
+
+	ticker := time.NewTicker(writeFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-u.writeLoopDone:
+			u.flushWriteQueues() // catch whatever was enqueued right before close
+			return
+		case <-ticker.C:
+			u.flushWriteQueues()
+		}
+	}
+}
+
+// flushWriteQueues drains everything currently pending across every client
+// queue and emits it with a single WriteBatch (sendmmsg) syscall, the same
+// batching strategy runBatched uses for reads.
+func (u *serverUDPListener) flushWriteQueues() {
+	u.writeQueuesMu.Lock()
+	queues := make([]*udpClientWriteQueue, 0, len(u.writeQueues))
+	for _, q := range u.writeQueues {
+		queues = append(queues, q)
+	}
+	u.writeQueuesMu.Unlock()
+
+	var msgs []ipv4.Message
+	for _, q := range queues {
+		msgs = append(msgs, drainWriteQueue(q)...)
+	}
+
+	if len(msgs) == 0 {
+		return
+	}
+
+	u.writeBatchMsgs(msgs) //nolint:errcheck
+}
+
+func drainWriteQueue(q *udpClientWriteQueue) []ipv4.Message {
+	var msgs []ipv4.Message
+
+	for {
+		select {
+		case buf := <-q.packets:
+			msgs = append(msgs, ipv4.Message{Buffers: [][]byte{buf}, Addr: q.addr})
+		default:
+			return msgs
+		}
+	}
+}
+
+// ServerUDPStats holds write-queue health counters for a serverUDPListener.
+type ServerUDPStats struct {
+	PacketsDropped uint64
+	QueueDepth     int
+}
+
+// ServerUDPStats reports the total number of packets dropped by full write
+// queues, and their combined current depth, across every client of u.
+func (u *serverUDPListener) ServerUDPStats() ServerUDPStats {
+	u.writeQueuesMu.Lock()
+	defer u.writeQueuesMu.Unlock()
+
+	var stats ServerUDPStats
+	for _, q := range u.writeQueues {
+		stats.PacketsDropped += atomic.LoadUint64(&q.dropped)
+		stats.QueueDepth += len(q.packets)
+	}
+
+	return stats
+}
+
+// writeBatch emits buf to every address in addrs with a single sendmmsg
+// syscall, used to fan a multicast-sourced packet out to many subscribers
+// without one WriteTo call each.
+func (u *serverUDPListener) writeBatch(buf []byte, addrs []*net.UDPAddr) error {
+	msgs := make([]ipv4.Message, len(addrs))
+	for i, addr := range addrs {
+		msgs[i].Buffers = [][]byte{buf}
+		msgs[i].Addr = addr
+	}
+
+	return u.writeBatchMsgs(msgs)
+}
+
+// writeBatchMsgs is the common WriteBatch call behind writeBatch and
+// flushWriteQueues: a single sendmmsg syscall for an arbitrary set of
+// (buffer, destination) pairs, with u.writeTimeout applied once for the
+// whole batch.
+func (u *serverUDPListener) writeBatchMsgs(msgs []ipv4.Message) error {
 	u.pc.SetWriteDeadline(time.Now().Add(u.writeTimeout))
-	_, err := u.pc.WriteTo(buf, addr)
-	return err
+
+	n, err := u.pcBatch.WriteBatch(msgs, 0)
+	if err != nil {
+		return err
+	}
+	if n != len(msgs) {
+		return fmt.Errorf("WriteBatch: wrote %d/%d messages", n, len(msgs))
+	}
+
+	return nil
 }
 
-func (u *serverUDPListener) addClient(ip net.IP, port int, cb readFunc) {
+// addClient registers cb to receive packets from ip:port. For a multicast
+// listener (one created with multicast=true), group must always be set to
+// its destination multicast group, since runMulticast keys every received
+// packet the same way regardless of whether the port is currently shared
+// with another group. Pass nil for a unicast (non-multicast) listener.
+func (u *serverUDPListener) addClient(group net.IP, ip net.IP, port int, cb readFunc) {
 	var addr clientAddr
-	addr.fill(ip, port)
+	addr.fill(group, ip, port)
 
 	u.clientsMutex.Lock()
 	defer u.clientsMutex.Unlock()
@@ -223,12 +822,13 @@ func (u *serverUDPListener) addClient(ip net.IP, port int, cb readFunc) {
 	u.clients[addr] = cb
 }
 
-func (u *serverUDPListener) removeClient(ip net.IP, port int) {
+func (u *serverUDPListener) removeClient(group net.IP, ip net.IP, port int) {
 	var addr clientAddr
-	addr.fill(ip, port)
+	addr.fill(group, ip, port)
 
 	u.clientsMutex.Lock()
-	defer u.clientsMutex.Unlock()
-
 	delete(u.clients, addr)
-}
\ No newline at end of file
+	u.clientsMutex.Unlock()
+
+	u.closeWriteQueue(addr)
+}
@@ -0,0 +1,122 @@
+package gortsplib
+
+import (
+	"net"
+	"runtime"
+	"time"
+)
+
+// serverUDPListenerPool wraps several serverUDPListener workers bound to the
+// same port via SO_REUSEPORT, so reads are spread across multiple goroutines
+// and kernel socket buffers instead of serializing through a single one.
+// It is only used for non-multicast listeners: a multicast group must be
+// joined on a single shared socket, which newServerUDPListener's
+// multicastListeners registry already takes care of.
+type serverUDPListenerPool struct {
+	workers []*serverUDPListener
+}
+
+// newServerUDPListenerPool opens conf.UDPListenerWorkers (or
+// runtime.GOMAXPROCS(0), if unset) SO_REUSEPORT listeners on address. On
+// platforms without SO_REUSEPORT (see reusePortSupported), a second bind of
+// the same port would fail with EADDRINUSE, so it falls back to a single
+// worker there regardless of conf.UDPListenerWorkers.
+func newServerUDPListenerPool(
+	writeTimeout time.Duration,
+	address string,
+	conf ServerConf,
+) (*serverUDPListenerPool, error) {
+	workerCount := conf.UDPListenerWorkers
+	if workerCount <= 0 {
+		workerCount = runtime.GOMAXPROCS(0)
+	}
+	if !reusePortSupported {
+		workerCount = 1
+	}
+
+	p := &serverUDPListenerPool{
+		workers: make([]*serverUDPListener, 0, workerCount),
+	}
+
+	for i := 0; i < workerCount; i++ {
+		w, err := newServerUDPListener(reusePortListenPacket, writeTimeout, false, address, conf)
+		if err != nil {
+			p.close()
+			return nil, err
+		}
+
+		p.workers = append(p.workers, w)
+	}
+
+	return p, nil
+}
+
+func (p *serverUDPListenerPool) close() {
+	for _, w := range p.workers {
+		w.close()
+	}
+}
+
+func (p *serverUDPListenerPool) ip() net.IP {
+	return p.workers[0].ip()
+}
+
+func (p *serverUDPListenerPool) port() int {
+	return p.workers[0].port()
+}
+
+// addClient registers cb on every worker, since the kernel may route a given
+// client's datagrams to any one of them.
+func (p *serverUDPListenerPool) addClient(group net.IP, ip net.IP, port int, cb readFunc) {
+	for _, w := range p.workers {
+		w.addClient(group, ip, port, cb)
+	}
+}
+
+func (p *serverUDPListenerPool) removeClient(group net.IP, ip net.IP, port int) {
+	for _, w := range p.workers {
+		w.removeClient(group, ip, port)
+	}
+}
+
+// write delegates to queuedWrite with a nil group, like serverUDPListener.write.
+func (p *serverUDPListenerPool) write(buf []byte, addr *net.UDPAddr) error {
+	return p.queuedWrite(nil, buf, addr)
+}
+
+// queuedWrite dispatches to the same worker for a given destination every
+// time (rather than rotating), so that a client's per-destination write
+// queue and ordering stay on a single worker.
+func (p *serverUDPListenerPool) queuedWrite(group net.IP, buf []byte, addr *net.UDPAddr) error {
+	var key clientAddr
+	key.fill(group, addr.IP, addr.Port)
+
+	i := clientAddrHash(key) % uint32(len(p.workers))
+	return p.workers[i].queuedWrite(group, buf, addr)
+}
+
+// clientAddrHash is a cheap FNV-1a hash of a clientAddr, used to pick a
+// stable worker for a given destination.
+func clientAddrHash(key clientAddr) uint32 {
+	h := uint32(2166136261)
+	for _, b := range key.ip {
+		h = (h ^ uint32(b)) * 16777619
+	}
+	for _, b := range key.group {
+		h = (h ^ uint32(b)) * 16777619
+	}
+	h = (h ^ uint32(key.port)) * 16777619
+	return h
+}
+
+// ServerUDPStats aggregates write-queue counters across every worker.
+func (p *serverUDPListenerPool) ServerUDPStats() ServerUDPStats {
+	var stats ServerUDPStats
+	for _, w := range p.workers {
+		s := w.ServerUDPStats()
+		stats.PacketsDropped += s.PacketsDropped
+		stats.QueueDepth += s.QueueDepth
+	}
+
+	return stats
+}
@@ -0,0 +1,42 @@
+package gortsplib
+
+import (
+	"net"
+	"testing"
+)
+
+func TestClientAddrHashStableAcrossCalls(t *testing.T) {
+	var key clientAddr
+	key.fill(nil, net.ParseIP("192.0.2.10"), 5004)
+
+	h1 := clientAddrHash(key)
+	h2 := clientAddrHash(key)
+	if h1 != h2 {
+		t.Fatalf("clientAddrHash is not deterministic: %d != %d", h1, h2)
+	}
+
+	// the worker a destination lands on must stay fixed for a given worker
+	// count, since queuedWrite relies on it to keep a client's queue (and
+	// ordering) on a single worker.
+	const workerCount = 4
+	want := h1 % workerCount
+	for i := 0; i < 10; i++ {
+		if got := clientAddrHash(key) % workerCount; got != want {
+			t.Fatalf("worker selection changed across calls: got %d, want %d", got, want)
+		}
+	}
+}
+
+func TestClientAddrHashSpreadsDistinctClients(t *testing.T) {
+	seen := make(map[uint32]bool)
+
+	for port := 5000; port < 5010; port++ {
+		var key clientAddr
+		key.fill(nil, net.ParseIP("192.0.2.10"), port)
+		seen[clientAddrHash(key)%8] = true
+	}
+
+	if len(seen) < 2 {
+		t.Fatalf("expected distinct clients to spread across more than one worker bucket, got %d", len(seen))
+	}
+}
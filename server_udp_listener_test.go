@@ -0,0 +1,103 @@
+package gortsplib
+
+import (
+	"errors"
+	"net"
+	"syscall"
+	"testing"
+)
+
+func TestIsReadBatchUnsupported(t *testing.T) {
+	for _, ca := range []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"eopnotsupp", syscall.EOPNOTSUPP, true},
+		{"wrapped eopnotsupp", &netOpError{syscall.EOPNOTSUPP}, true},
+		{"other errno", syscall.EINVAL, false},
+		{"unrelated error", errors.New("some other failure"), false},
+	} {
+		t.Run(ca.name, func(t *testing.T) {
+			if got := isReadBatchUnsupported(ca.err); got != ca.want {
+				t.Errorf("isReadBatchUnsupported(%v) = %v, want %v", ca.err, got, ca.want)
+			}
+		})
+	}
+}
+
+// netOpError mimics the way the net package typically wraps a syscall errno,
+// so the errors.Is(err, syscall.EOPNOTSUPP) check in isReadBatchUnsupported
+// is exercised through an Unwrap chain rather than the bare errno.
+type netOpError struct {
+	err error
+}
+
+func (e *netOpError) Error() string { return "netOpError: " + e.err.Error() }
+func (e *netOpError) Unwrap() error { return e.err }
+
+func TestClientAddrFillV4InV6(t *testing.T) {
+	var v4, v6mapped clientAddr
+	v4.fill(nil, net.ParseIP("192.0.2.10").To4(), 5004) // 4-byte form
+	v6mapped.fill(nil, net.ParseIP("192.0.2.10"), 5004) // net.ParseIP already returns the 16-byte v4-in-v6 form
+
+	if v4 != v6mapped {
+		t.Fatalf("a 4-byte IPv4 and its IPv4-in-IPv6 form should key identically, got %v != %v", v4, v6mapped)
+	}
+
+	var v6 clientAddr
+	v6.fill(nil, net.ParseIP("2001:db8::1"), 5004)
+	if v4 == v6 {
+		t.Fatalf("a distinct IPv6 address must not collide with the IPv4 one")
+	}
+}
+
+func TestDispatchKeysByMulticastGroup(t *testing.T) {
+	u := &serverUDPListener{clients: make(map[clientAddr]readFunc)}
+
+	srcIP := net.ParseIP("198.51.100.5")
+	const srcPort = 6000
+
+	var gotGroup1, gotGroup2 []byte
+	u.addClient(net.ParseIP("239.0.0.1"), srcIP, srcPort, func(data []byte) { gotGroup1 = data })
+	u.addClient(net.ParseIP("239.0.0.2"), srcIP, srcPort, func(data []byte) { gotGroup2 = data })
+
+	u.dispatch(net.ParseIP("239.0.0.1"), srcIP, srcPort, []byte("for group 1"))
+	if string(gotGroup1) != "for group 1" {
+		t.Fatalf("client registered for group 1 did not receive its packet, got %q", gotGroup1)
+	}
+	if gotGroup2 != nil {
+		t.Fatalf("client registered for group 2 must not receive group 1's packet, got %q", gotGroup2)
+	}
+
+	u.dispatch(net.ParseIP("239.0.0.2"), srcIP, srcPort, []byte("for group 2"))
+	if string(gotGroup2) != "for group 2" {
+		t.Fatalf("client registered for group 2 did not receive its packet, got %q", gotGroup2)
+	}
+}
+
+func TestWriteQueueDropAccounting(t *testing.T) {
+	u := &serverUDPListener{
+		writeQueueSize: 1,
+		writeQueues:    make(map[clientAddr]*udpClientWriteQueue),
+	}
+
+	addr := &net.UDPAddr{IP: net.ParseIP("198.51.100.9"), Port: 7000}
+
+	if err := u.queuedWrite(nil, []byte("first"), addr); err != nil {
+		t.Fatalf("first enqueue into an empty queue should not be dropped: %v", err)
+	}
+
+	err := u.queuedWrite(nil, []byte("second"), addr)
+	if !errors.Is(err, errUDPWriteQueueFull) {
+		t.Fatalf("second enqueue into a full queue should report errUDPWriteQueueFull, got %v", err)
+	}
+
+	stats := u.ServerUDPStats()
+	if stats.PacketsDropped != 1 {
+		t.Fatalf("expected 1 dropped packet, got %d", stats.PacketsDropped)
+	}
+	if stats.QueueDepth != 1 {
+		t.Fatalf("expected the surviving packet to still be queued, got depth %d", stats.QueueDepth)
+	}
+}
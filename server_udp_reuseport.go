@@ -0,0 +1,39 @@
+//go:build linux || darwin
+
+package gortsplib
+
+import (
+	"context"
+	"net"
+	"syscall"
+)
+
+// reusePortSupported is true here: SO_REUSEPORT lets newServerUDPListenerPool
+// open more than one worker on the same port.
+const reusePortSupported = true
+
+// reusePortListenPacket opens a UDP socket with SO_REUSEPORT and SO_REUSEADDR
+// set, so that multiple serverUDPListenerPool workers can bind the same port
+// and let the kernel load-balance incoming datagrams across them.
+func reusePortListenPacket(network, address string) (net.PacketConn, error) {
+	lc := net.ListenConfig{
+		Control: func(_, _ string, c syscall.RawConn) error {
+			var sockErr error
+
+			err := c.Control(func(fd uintptr) {
+				sockErr = syscall.SetsockoptInt(int(fd), syscall.SOL_SOCKET, syscall.SO_REUSEPORT, 1)
+				if sockErr != nil {
+					return
+				}
+				sockErr = syscall.SetsockoptInt(int(fd), syscall.SOL_SOCKET, syscall.SO_REUSEADDR, 1)
+			})
+			if err != nil {
+				return err
+			}
+
+			return sockErr
+		},
+	}
+
+	return lc.ListenPacket(context.Background(), network, address)
+}
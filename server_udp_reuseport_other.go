@@ -0,0 +1,16 @@
+//go:build !linux && !darwin
+
+package gortsplib
+
+import "net"
+
+// reusePortSupported is false here: without SO_REUSEPORT, a second bind of
+// the same UDP port fails with EADDRINUSE, so newServerUDPListenerPool forces
+// a single worker on these platforms instead of trying to open more.
+const reusePortSupported = false
+
+// reusePortListenPacket falls back to a plain listener on platforms without
+// SO_REUSEPORT support.
+func reusePortListenPacket(network, address string) (net.PacketConn, error) {
+	return net.ListenPacket(network, address)
+}